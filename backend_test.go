@@ -0,0 +1,44 @@
+package systemctl
+
+import (
+	"context"
+	"testing"
+)
+
+func TestUseDBus(t *testing.T) {
+	ctx := context.Background()
+	testCases := []struct {
+		name    string
+		backend Backend
+		probe   bool
+		want    bool
+	}{
+		{"exec forced", BackendExec, true, false},
+		{"dbus forced", BackendDBus, false, true},
+		{"auto with bus", BackendAuto, true, true},
+		{"auto without bus", BackendAuto, false, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			orig := dbusAvailable
+			dbusAvailable = func(context.Context, Options) bool { return tc.probe }
+			defer func() { dbusAvailable = orig }()
+
+			got := useDBus(ctx, Options{Backend: tc.backend})
+			if got != tc.want {
+				t.Errorf("useDBus() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUseDBusSkipsProbeWhenExecerIsSet(t *testing.T) {
+	orig := dbusAvailable
+	dbusAvailable = func(context.Context, Options) bool { return true }
+	defer func() { dbusAvailable = orig }()
+
+	got := useDBus(context.Background(), Options{Backend: BackendAuto, Executer: execer{}})
+	if got {
+		t.Errorf("useDBus() = %v, want false: an injected Executer should force the exec path under BackendAuto", got)
+	}
+}
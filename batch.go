@@ -0,0 +1,190 @@
+package systemctl
+
+import (
+	"context"
+	"fmt"
+)
+
+// OutcomeStatus describes what ultimately happened to one unit in a batch
+// operation.
+type OutcomeStatus int
+
+const (
+	// Applied means the operation succeeded and, in atomic mode, was not
+	// rolled back.
+	Applied OutcomeStatus = iota
+	// RolledBack means the operation succeeded but was reversed after a
+	// later unit in the same atomic batch failed.
+	RolledBack
+	// Skipped means the unit ends up neither Applied nor RolledBack: it's the
+	// unit whose operation failed, a later unit in an atomic batch that
+	// stopped early (never attempted), a unit that was already in the target
+	// state and so needed no rollback, or, in RestartMany, a restarted unit
+	// that has no inverse to roll back with.
+	Skipped
+	// FailedRollback means the operation succeeded but reversing it also
+	// failed; the unit is left in the post-apply state and needs manual
+	// attention.
+	FailedRollback
+)
+
+func (s OutcomeStatus) String() string {
+	switch s {
+	case Applied:
+		return "Applied"
+	case RolledBack:
+		return "RolledBack"
+	case Skipped:
+		return "Skipped"
+	case FailedRollback:
+		return "FailedRollback"
+	default:
+		return "Unknown"
+	}
+}
+
+// UnitOutcome records what happened to a single unit within a batch call.
+type UnitOutcome struct {
+	Unit   string
+	Status OutcomeStatus
+	Err    error
+}
+
+// BatchError is returned by the *Many functions whenever at least one unit
+// failed. It wraps the error that triggered the failure along with a
+// per-unit breakdown of what was applied, rolled back, or skipped.
+type BatchError struct {
+	// Unit is the first unit whose operation failed.
+	Unit string
+	// Err is the error that unit's operation returned.
+	Err error
+	// Outcomes describes every unit passed to the batch call, in order.
+	Outcomes []UnitOutcome
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("systemctl: batch operation failed on %q: %v", e.Unit, e.Err)
+}
+
+func (e *BatchError) Unwrap() error { return e.Err }
+
+type opFunc func(ctx context.Context, unit string, opts Options) error
+
+// alreadyFunc reports whether a unit already satisfies the state an apply
+// would put it in, so atomic rollback knows not to touch units the batch
+// didn't actually change.
+type alreadyFunc func(ctx context.Context, unit string, opts Options) (bool, error)
+
+// runMany drives apply across units, optionally rolling back the successful
+// subset via rollback when one fails and opts.Atomic is set. already is
+// consulted, in atomic mode only, to avoid rolling back units that were
+// already in the target state before this call touched them.
+func runMany(ctx context.Context, units []string, opts Options, apply, rollback opFunc, already alreadyFunc) error {
+	outcomes := make([]UnitOutcome, len(units))
+	noop := make([]bool, len(units))
+
+	var (
+		batchErr   error
+		failedUnit string
+	)
+
+	for i, unit := range units {
+		if opts.Atomic && batchErr != nil {
+			outcomes[i] = UnitOutcome{Unit: unit, Status: Skipped}
+			continue
+		}
+
+		if opts.Atomic && already != nil {
+			wasAlready, err := already(ctx, unit, opts)
+			if err == nil {
+				noop[i] = wasAlready
+			}
+		}
+
+		if err := apply(ctx, unit, opts); err != nil {
+			if batchErr == nil {
+				batchErr = err
+				failedUnit = unit
+			}
+			outcomes[i] = UnitOutcome{Unit: unit, Status: Skipped, Err: err}
+			continue
+		}
+		outcomes[i] = UnitOutcome{Unit: unit, Status: Applied}
+	}
+
+	if batchErr == nil {
+		return nil
+	}
+
+	if opts.Atomic {
+		for i := range outcomes {
+			if outcomes[i].Status != Applied {
+				continue
+			}
+			if noop[i] || rollback == nil {
+				outcomes[i].Status = Skipped
+				continue
+			}
+			if err := rollback(ctx, outcomes[i].Unit, opts); err != nil {
+				outcomes[i].Status = FailedRollback
+				outcomes[i].Err = err
+			} else {
+				outcomes[i].Status = RolledBack
+			}
+		}
+	}
+
+	return &BatchError{Unit: failedUnit, Err: batchErr, Outcomes: outcomes}
+}
+
+func isEnabled(ctx context.Context, unit string, opts Options) (bool, error) {
+	return IsEnabled(ctx, unit, opts)
+}
+
+func isDisabled(ctx context.Context, unit string, opts Options) (bool, error) {
+	enabled, err := IsEnabled(ctx, unit, opts)
+	return !enabled, err
+}
+
+func isActive(ctx context.Context, unit string, opts Options) (bool, error) {
+	return IsActive(ctx, unit, opts)
+}
+
+func isInactive(ctx context.Context, unit string, opts Options) (bool, error) {
+	active, err := IsActive(ctx, unit, opts)
+	return !active, err
+}
+
+// EnableMany enables each unit in units. When opts.Atomic is set, the first
+// failure reverses every unit this call actually enabled (units already
+// enabled beforehand are left alone) and returns a *BatchError; otherwise it
+// keeps going past failures and still reports them via *BatchError.
+func EnableMany(ctx context.Context, units []string, opts Options) error {
+	return runMany(ctx, units, opts, Enable, Disable, isEnabled)
+}
+
+// DisableMany disables each unit in units, with the same atomic-rollback
+// semantics as EnableMany.
+func DisableMany(ctx context.Context, units []string, opts Options) error {
+	return runMany(ctx, units, opts, Disable, Enable, isDisabled)
+}
+
+// StartMany starts each unit in units, with the same atomic-rollback
+// semantics as EnableMany.
+func StartMany(ctx context.Context, units []string, opts Options) error {
+	return runMany(ctx, units, opts, Start, Stop, isActive)
+}
+
+// StopMany stops each unit in units, with the same atomic-rollback semantics
+// as EnableMany.
+func StopMany(ctx context.Context, units []string, opts Options) error {
+	return runMany(ctx, units, opts, Stop, Start, isInactive)
+}
+
+// RestartMany restarts each unit in units. Restart has no meaningful
+// inverse, so in atomic mode a later failure leaves earlier restarts in
+// place (reported as Skipped rather than RolledBack) instead of restarting
+// them again.
+func RestartMany(ctx context.Context, units []string, opts Options) error {
+	return runMany(ctx, units, opts, Restart, nil, nil)
+}
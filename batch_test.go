@@ -0,0 +1,61 @@
+package systemctl_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	systemctl "github.com/taigrr/systemctl"
+	"github.com/taigrr/systemctl/systemctltest"
+)
+
+func TestEnableManyAtomicRollback(t *testing.T) {
+	fake := &systemctltest.Fake{}
+	fake.When([]string{"is-enabled", "already-enabled"}, systemctltest.Result{Code: 0, Stdout: "enabled\n"})
+	fake.When([]string{"is-enabled", "newly-enabled"}, systemctltest.Result{Code: 1, Stdout: "disabled\n"})
+	fake.When([]string{"is-enabled", "broken"}, systemctltest.Result{Code: 1, Stdout: "disabled\n"})
+	fake.When([]string{"enable", "already-enabled"}, systemctltest.Result{Code: 0})
+	fake.When([]string{"enable", "newly-enabled"}, systemctltest.Result{Code: 0})
+	fake.When([]string{"enable", "broken"}, systemctltest.Result{Code: 1, Stderr: "Unit broken.service does not exist."})
+	fake.When([]string{"disable", "newly-enabled"}, systemctltest.Result{Code: 0})
+
+	opts := systemctl.Options{Atomic: true, Executer: fake, Backend: systemctl.BackendExec}
+	err := systemctl.EnableMany(context.Background(), []string{"already-enabled", "newly-enabled", "broken"}, opts)
+
+	var batchErr *systemctl.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("expected a *BatchError, got %v", err)
+	}
+	if batchErr.Unit != "broken" {
+		t.Errorf("Unit = %q, want %q", batchErr.Unit, "broken")
+	}
+	want := map[string]systemctl.OutcomeStatus{
+		"already-enabled": systemctl.Skipped,
+		"newly-enabled":   systemctl.RolledBack,
+		"broken":          systemctl.Skipped,
+	}
+	for _, o := range batchErr.Outcomes {
+		if o.Status != want[o.Unit] {
+			t.Errorf("outcome for %q = %v, want %v", o.Unit, o.Status, want[o.Unit])
+		}
+	}
+}
+
+func TestEnableManyNonAtomicContinuesPastFailures(t *testing.T) {
+	fake := &systemctltest.Fake{}
+	fake.When([]string{"enable", "broken"}, systemctltest.Result{Code: 1, Stderr: "Unit broken.service does not exist."})
+	fake.When([]string{"enable", "ok"}, systemctltest.Result{Code: 0})
+
+	opts := systemctl.Options{Executer: fake, Backend: systemctl.BackendExec}
+	err := systemctl.EnableMany(context.Background(), []string{"broken", "ok"}, opts)
+
+	var batchErr *systemctl.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("expected a *BatchError, got %v", err)
+	}
+	for _, o := range batchErr.Outcomes {
+		if o.Unit == "ok" && o.Status != systemctl.Applied {
+			t.Errorf("outcome for %q = %v, want %v", o.Unit, o.Status, systemctl.Applied)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package systemctl
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/taigrr/systemctl/properties"
+)
+
+const (
+	systemdDest       = "org.freedesktop.systemd1"
+	systemdObjectPath = dbus.ObjectPath("/org/freedesktop/systemd1")
+	managerIface      = "org.freedesktop.systemd1.Manager"
+	unitIface         = "org.freedesktop.systemd1.Unit"
+	propertiesIface   = "org.freedesktop.DBus.Properties"
+)
+
+// connectBus opens a connection to the session bus (UserMode) or system bus,
+// honoring ctx's deadline for the handshake.
+func connectBus(ctx context.Context, opts Options) (*dbus.Conn, error) {
+	var (
+		conn *dbus.Conn
+		err  error
+	)
+	if opts.UserMode {
+		conn, err = dbus.ConnectSessionBus(dbus.WithContext(ctx))
+	} else {
+		conn, err = dbus.ConnectSystemBus(dbus.WithContext(ctx))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// translateDBusError maps the errors systemd reports over the bus to this
+// package's sentinel errors, so code written against the exec backend keeps
+// working unmodified against the D-Bus backend.
+func translateDBusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	dbusErr, ok := err.(dbus.Error)
+	if !ok {
+		return ErrBusFailure
+	}
+	switch dbusErr.Name {
+	case "org.freedesktop.systemd1.NoSuchUnit", "org.freedesktop.systemd1.LoadFailed":
+		return ErrDoesNotExist
+	case "org.freedesktop.systemd1.UnitMasked":
+		return ErrMasked
+	case "org.freedesktop.DBus.Error.AccessDenied", "org.freedesktop.PolicyKit1.Error.NotAuthorized":
+		return ErrInsufficientPermissions
+	default:
+		return ErrUnknown
+	}
+}
+
+// loadUnitPath resolves unit's manager object path via LoadUnit rather than
+// GetUnit: GetUnit only knows about units systemd has already loaded into
+// memory, so it spuriously reports NoSuchUnit for an installed-but-not-yet-
+// started unit, whereas LoadUnit loads it on demand the same way the
+// `systemctl show`/`is-active`/etc CLI commands do under the hood.
+func loadUnitPath(ctx context.Context, conn *dbus.Conn, unit string) (dbus.ObjectPath, error) {
+	managerObj := conn.Object(systemdDest, systemdObjectPath)
+	var unitPath dbus.ObjectPath
+	if err := managerObj.CallWithContext(ctx, managerIface+".LoadUnit", 0, unit).Store(&unitPath); err != nil {
+		return "", translateDBusError(err)
+	}
+	return unitPath, nil
+}
+
+func callManager(ctx context.Context, opts Options, method string, args ...interface{}) error {
+	conn, err := connectBus(ctx, opts)
+	if err != nil {
+		return ErrBusFailure
+	}
+	defer conn.Close()
+	obj := conn.Object(systemdDest, systemdObjectPath)
+	call := obj.CallWithContext(ctx, managerIface+"."+method, 0, args...)
+	if call.Err != nil {
+		return translateDBusError(call.Err)
+	}
+	return nil
+}
+
+func dbusEnable(ctx context.Context, unit string, opts Options) error {
+	// EnableUnitFiles(files []string, runtime bool, force bool) (carries_install_info bool, changes []EnableUnitFileChange)
+	// force mirrors the CLI's --force (opts.Force): without it, a
+	// conflicting pre-existing symlink is an error on both backends rather
+	// than something D-Bus silently overwrites.
+	return callManager(ctx, opts, "EnableUnitFiles", []string{unit}, false, opts.Force)
+}
+
+func dbusDisable(ctx context.Context, unit string, opts Options) error {
+	return callManager(ctx, opts, "DisableUnitFiles", []string{unit}, false)
+}
+
+func dbusMask(ctx context.Context, unit string, opts Options) error {
+	return callManager(ctx, opts, "MaskUnitFiles", []string{unit}, false, opts.Force)
+}
+
+func dbusUnmask(ctx context.Context, unit string, opts Options) error {
+	return callManager(ctx, opts, "UnmaskUnitFiles", []string{unit}, false)
+}
+
+func dbusStart(ctx context.Context, unit string, opts Options) error {
+	return callManager(ctx, opts, "StartUnit", unit, "replace")
+}
+
+func dbusStop(ctx context.Context, unit string, opts Options) error {
+	return callManager(ctx, opts, "StopUnit", unit, "replace")
+}
+
+func dbusRestart(ctx context.Context, unit string, opts Options) error {
+	return callManager(ctx, opts, "RestartUnit", unit, "replace")
+}
+
+func dbusDaemonReload(ctx context.Context, opts Options) error {
+	return callManager(ctx, opts, "Reload")
+}
+
+// unitProperty fetches a single property off the given unit's object path
+// via org.freedesktop.DBus.Properties.Get.
+func unitProperty(ctx context.Context, unit string, property properties.Property, opts Options) (dbus.Variant, error) {
+	conn, err := connectBus(ctx, opts)
+	if err != nil {
+		return dbus.Variant{}, ErrBusFailure
+	}
+	defer conn.Close()
+
+	unitPath, err := loadUnitPath(ctx, conn, unit)
+	if err != nil {
+		return dbus.Variant{}, err
+	}
+
+	unitObj := conn.Object(systemdDest, unitPath)
+	var value dbus.Variant
+	call := unitObj.CallWithContext(ctx, propertiesIface+".Get", 0, unitIface, string(property))
+	if call.Err != nil {
+		return dbus.Variant{}, translateDBusError(call.Err)
+	}
+	if err := call.Store(&value); err != nil {
+		return dbus.Variant{}, err
+	}
+	return value, nil
+}
+
+func dbusShow(ctx context.Context, unit string, property properties.Property, opts Options) (string, error) {
+	value, err := unitProperty(ctx, unit, property, opts)
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(value.String(), `"`), nil
+}
+
+func dbusIsActive(ctx context.Context, unit string, opts Options) (bool, error) {
+	value, err := dbusShow(ctx, unit, properties.ActiveState, opts)
+	if err != nil {
+		return false, err
+	}
+	return value == "active", nil
+}
+
+func dbusIsEnabled(ctx context.Context, unit string, opts Options) (bool, error) {
+	value, err := dbusShow(ctx, unit, properties.UnitFileState, opts)
+	if err != nil {
+		return false, err
+	}
+	return value == "enabled", nil
+}
+
+func dbusIsFailed(ctx context.Context, unit string, opts Options) (bool, error) {
+	value, err := dbusShow(ctx, unit, properties.ActiveState, opts)
+	if err != nil {
+		return false, err
+	}
+	return value == "failed", nil
+}
+
+// ShowTyped behaves like Show but converts the property's value into a
+// native Go type where this package knows how: bool for the usual boolean
+// unit properties, time.Time for "*Timestamp" properties, time.Duration for
+// "*USec" properties, and the D-Bus value's own Go type otherwise (e.g.
+// string, uint32). It requires the D-Bus backend,
+// since the exec backend only ever sees stringified output.
+func ShowTyped(ctx context.Context, unit string, property properties.Property, opts Options) (interface{}, error) {
+	if opts.Backend == BackendExec {
+		return nil, ErrBusFailure
+	}
+	opts.Backend = BackendDBus
+	value, err := unitProperty(ctx, unit, property, opts)
+	if err != nil {
+		return nil, err
+	}
+	return convertTypedProperty(property, value), nil
+}
+
+func convertTypedProperty(property properties.Property, value dbus.Variant) interface{} {
+	name := string(property)
+	switch {
+	case strings.HasSuffix(name, "Timestamp"):
+		if usec, ok := value.Value().(uint64); ok {
+			return time.UnixMicro(int64(usec))
+		}
+	case strings.HasSuffix(name, "USec"):
+		if usec, ok := value.Value().(uint64); ok {
+			return time.Duration(usec) * time.Microsecond
+		}
+	}
+	return value.Value()
+}
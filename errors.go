@@ -0,0 +1,34 @@
+package systemctl
+
+import "errors"
+
+// Sentinel errors returned by the functions in this package. Callers are
+// expected to compare against these with errors.Is rather than inspecting
+// exit codes or parsing stderr themselves.
+var (
+	// ErrDoesNotExist is returned when the requested unit is not known to
+	// systemd.
+	ErrDoesNotExist = errors.New("unit does not exist")
+
+	// ErrInsufficientPermissions is returned when the caller does not have
+	// the privileges required to perform the requested operation, e.g.
+	// managing a system unit as a non-root user.
+	ErrInsufficientPermissions = errors.New("insufficient permissions")
+
+	// ErrExecutableNotFound is returned when the systemctl binary cannot be
+	// located on $PATH.
+	ErrExecutableNotFound = errors.New("systemctl executable not found")
+
+	// ErrMasked is returned when an operation fails because the target unit
+	// is masked.
+	ErrMasked = errors.New("unit is masked")
+
+	// ErrBusFailure is returned when systemd itself reports a failure
+	// talking to the bus, e.g. a mismatched --user flag against the
+	// manager that owns the unit.
+	ErrBusFailure = errors.New("bus failure")
+
+	// ErrUnknown is returned when systemctl exits non-zero for a reason this
+	// package does not otherwise recognize.
+	ErrUnknown = errors.New("unknown error")
+)
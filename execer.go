@@ -0,0 +1,65 @@
+package systemctl
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+)
+
+// Execer abstracts running the systemctl binary so the package's behavior
+// can be exercised without a real systemd, root, or any installed units.
+// The real implementation, execer, shells out via os/exec; tests inject a
+// fake that matches against expected argv and returns canned results. See
+// the systemctltest sub-package for a ready-made fake.
+type Execer interface {
+	// Run executes `name args...`, waits for it to finish, and reports its
+	// stdout, stderr, and exit code. err is non-nil only when the command
+	// could not be started or waited on (e.g. the binary is missing); a
+	// non-zero exit from a command that ran is reported via code, not err.
+	Run(ctx context.Context, name string, args ...string) (stdout, stderr string, code int, err error)
+}
+
+// defaultExecer is used whenever a call's Options.Executer is nil. It is
+// package-level state rather than a parameter so existing call sites don't
+// need to be touched to opt into a fake during tests.
+var defaultExecer Execer = execer{}
+
+// SetExecer overrides the package-wide default Execer. It is meant to be
+// called once, typically from TestMain or an init-style setup, to point the
+// whole package at a fake for the duration of a test run. Passing nil
+// restores the real fork/exec implementation.
+func SetExecer(e Execer) {
+	if e == nil {
+		e = execer{}
+	}
+	defaultExecer = e
+}
+
+// execerFor resolves the Execer that should be used for a single call,
+// preferring a per-call override over the package default.
+func execerFor(opts Options) Execer {
+	if opts.Executer != nil {
+		return opts.Executer
+	}
+	return defaultExecer
+}
+
+// execer is the real Execer, implemented by forking and exec'ing the
+// systemctl binary found on $PATH.
+type execer struct{}
+
+func (execer) Run(ctx context.Context, name string, args ...string) (string, string, int, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stdout, stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	code := cmd.ProcessState.ExitCode()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return stdout.String(), stderr.String(), code, err
+		}
+	}
+	return stdout.String(), stderr.String(), code, nil
+}
@@ -0,0 +1,57 @@
+package systemctl
+
+import (
+	"time"
+
+	"github.com/taigrr/systemctl/properties"
+)
+
+// Options controls how a single call into this package is carried out.
+type Options struct {
+	// UserMode, when true, operates on the calling user's systemd instance
+	// (`systemctl --user ...`) rather than the system-wide one.
+	UserMode bool
+
+	// Executer, when set, overrides the package-level default executer for
+	// this call only. Most callers should use SetExecer instead; this field
+	// exists for tests and for code that needs to mix backends within the
+	// same process.
+	Executer Execer
+
+	// Backend selects whether this call talks to systemd over D-Bus or by
+	// forking systemctl. Defaults to BackendAuto.
+	Backend Backend
+
+	// Force mirrors systemctl's --force: for Enable/Mask, it replaces a
+	// conflicting pre-existing symlink instead of erroring on it. Defaults
+	// to false on both backends, matching plain `systemctl enable`/`mask`.
+	Force bool
+
+	// Elevate controls whether an exec-backend call runs systemctl through a
+	// privilege-escalation helper. The helper wraps the call from the start;
+	// a call that fails without it is not retried. Defaults to ElevateNever.
+	Elevate Elevate
+
+	// ElevateHelper overrides the helper binary Elevate would otherwise pick
+	// (pkexec or sudo), for callers with a custom wrapper script.
+	ElevateHelper string
+
+	// Atomic, used by the *Many batch functions, causes the first unit
+	// failure to roll back every unit the batch call already changed and
+	// stop, instead of continuing past it.
+	Atomic bool
+
+	// WatchProperties selects which unit properties Watch reports on.
+	// Defaults to ActiveState, SubState, LoadState, MainPID, ExecMainCode,
+	// and ExecMainStatus.
+	WatchProperties []properties.Property
+
+	// WatchInterval sets the polling interval Watch falls back to when the
+	// D-Bus backend isn't available. Defaults to 2 seconds.
+	WatchInterval time.Duration
+
+	// WatchCoalesce, when non-zero, drops a property transition if another
+	// transition of the same property was already emitted within this
+	// window, so a rapidly oscillating unit doesn't drown Watch's channel.
+	WatchCoalesce time.Duration
+}
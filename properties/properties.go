@@ -0,0 +1,37 @@
+// Package properties enumerates the systemd unit properties that
+// systemctl.Show knows how to query.
+package properties
+
+// Property names a single `systemctl show -p <Property>` field.
+type Property string
+
+// A selection of commonly-queried unit properties. This is not exhaustive;
+// any property name accepted by `systemctl show -p` may be passed to Show
+// even if it has no constant here.
+const (
+	ActiveState           Property = "ActiveState"
+	SubState              Property = "SubState"
+	LoadState             Property = "LoadState"
+	UnitFileState         Property = "UnitFileState"
+	MainPID               Property = "MainPID"
+	ExecMainCode          Property = "ExecMainCode"
+	ExecMainStatus        Property = "ExecMainStatus"
+	InactiveExitTimestamp Property = "InactiveExitTimestamp"
+	ActiveEnterTimestamp  Property = "ActiveEnterTimestamp"
+	ActiveExitTimestamp   Property = "ActiveExitTimestamp"
+)
+
+// Properties lists every Property constant defined above, in the order
+// TestShow and similar callers iterate over them.
+var Properties = []Property{
+	ActiveState,
+	SubState,
+	LoadState,
+	UnitFileState,
+	MainPID,
+	ExecMainCode,
+	ExecMainStatus,
+	InactiveExitTimestamp,
+	ActiveEnterTimestamp,
+	ActiveExitTimestamp,
+}
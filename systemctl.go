@@ -0,0 +1,273 @@
+// Package systemctl provides a thin, typed wrapper for managing systemd
+// units, either through the systemctl command line tool or over D-Bus (see
+// Backend).
+package systemctl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/taigrr/systemctl/properties"
+)
+
+const binaryName = "systemctl"
+
+// run executes `systemctl args...` (prefixing --user when opts.UserMode is
+// set) through whichever Execer this call resolves to, wrapping the
+// invocation in a privilege-escalation helper first when opts.Elevate calls
+// for one.
+func run(ctx context.Context, opts Options, args ...string) (stdout, stderr string, code int, err error) {
+	if opts.UserMode {
+		args = append([]string{"--user"}, args...)
+	}
+	name := binaryName
+	if helper, helperArgs, ok := elevationFor(opts); ok {
+		args = append(append(helperArgs, name), args...)
+		name = helper
+	}
+	stdout, stderr, code, err = execerFor(opts).Run(ctx, name, args...)
+	if err != nil {
+		return stdout, stderr, code, fmt.Errorf("%w: %v", ErrExecutableNotFound, err)
+	}
+	return stdout, stderr, code, nil
+}
+
+// mapError translates a systemctl exit code/stderr pair shared by the
+// enable/disable/start/stop/restart/mask/unmask family into one of this
+// package's sentinel errors.
+func mapError(code int, stderr string) error {
+	switch {
+	case code == 0:
+		return nil
+	case strings.Contains(stderr, "not found") || strings.Contains(stderr, "does not exist") || strings.Contains(stderr, "No such"):
+		return ErrDoesNotExist
+	case strings.Contains(stderr, "Access denied") || strings.Contains(stderr, "Permission denied") ||
+		strings.Contains(stderr, "Request dismissed") || strings.Contains(stderr, "Not authorized") ||
+		strings.Contains(stderr, "a password is required") || strings.Contains(stderr, "sorry, you must have a tty"):
+		return ErrInsufficientPermissions
+	case strings.Contains(stderr, "masked"):
+		return ErrMasked
+	case strings.Contains(stderr, "Failed to connect to bus") || strings.Contains(stderr, "Transport endpoint"):
+		return ErrBusFailure
+	default:
+		return ErrUnknown
+	}
+}
+
+// Enable runs `systemctl enable <unit>`.
+func Enable(ctx context.Context, unit string, opts Options) error {
+	if useDBus(ctx, opts) {
+		return dbusEnable(ctx, unit, opts)
+	}
+	return execEnable(ctx, unit, opts)
+}
+
+func execEnable(ctx context.Context, unit string, opts Options) error {
+	args := []string{"enable"}
+	if opts.Force {
+		args = append(args, "--force")
+	}
+	args = append(args, unit)
+	_, stderr, code, err := run(ctx, opts, args...)
+	if err != nil {
+		return err
+	}
+	return mapError(code, stderr)
+}
+
+// Disable runs `systemctl disable <unit>`.
+func Disable(ctx context.Context, unit string, opts Options) error {
+	if useDBus(ctx, opts) {
+		return dbusDisable(ctx, unit, opts)
+	}
+	return execDisable(ctx, unit, opts)
+}
+
+func execDisable(ctx context.Context, unit string, opts Options) error {
+	_, stderr, code, err := run(ctx, opts, "disable", unit)
+	if err != nil {
+		return err
+	}
+	return mapError(code, stderr)
+}
+
+// Start runs `systemctl start <unit>`.
+func Start(ctx context.Context, unit string, opts Options) error {
+	if useDBus(ctx, opts) {
+		return dbusStart(ctx, unit, opts)
+	}
+	return execStart(ctx, unit, opts)
+}
+
+func execStart(ctx context.Context, unit string, opts Options) error {
+	_, stderr, code, err := run(ctx, opts, "start", unit)
+	if err != nil {
+		return err
+	}
+	return mapError(code, stderr)
+}
+
+// Stop runs `systemctl stop <unit>`.
+func Stop(ctx context.Context, unit string, opts Options) error {
+	if useDBus(ctx, opts) {
+		return dbusStop(ctx, unit, opts)
+	}
+	return execStop(ctx, unit, opts)
+}
+
+func execStop(ctx context.Context, unit string, opts Options) error {
+	_, stderr, code, err := run(ctx, opts, "stop", unit)
+	if err != nil {
+		return err
+	}
+	return mapError(code, stderr)
+}
+
+// Restart runs `systemctl restart <unit>`.
+func Restart(ctx context.Context, unit string, opts Options) error {
+	if useDBus(ctx, opts) {
+		return dbusRestart(ctx, unit, opts)
+	}
+	return execRestart(ctx, unit, opts)
+}
+
+func execRestart(ctx context.Context, unit string, opts Options) error {
+	_, stderr, code, err := run(ctx, opts, "restart", unit)
+	if err != nil {
+		return err
+	}
+	return mapError(code, stderr)
+}
+
+// Mask runs `systemctl mask <unit>`.
+func Mask(ctx context.Context, unit string, opts Options) error {
+	if useDBus(ctx, opts) {
+		return dbusMask(ctx, unit, opts)
+	}
+	return execMask(ctx, unit, opts)
+}
+
+func execMask(ctx context.Context, unit string, opts Options) error {
+	args := []string{"mask"}
+	if opts.Force {
+		args = append(args, "--force")
+	}
+	args = append(args, unit)
+	_, stderr, code, err := run(ctx, opts, args...)
+	if err != nil {
+		return err
+	}
+	return mapError(code, stderr)
+}
+
+// Unmask runs `systemctl unmask <unit>`.
+func Unmask(ctx context.Context, unit string, opts Options) error {
+	if useDBus(ctx, opts) {
+		return dbusUnmask(ctx, unit, opts)
+	}
+	return execUnmask(ctx, unit, opts)
+}
+
+func execUnmask(ctx context.Context, unit string, opts Options) error {
+	_, stderr, code, err := run(ctx, opts, "unmask", unit)
+	if err != nil {
+		return err
+	}
+	return mapError(code, stderr)
+}
+
+// IsActive reports whether the unit is currently active.
+func IsActive(ctx context.Context, unit string, opts Options) (bool, error) {
+	if useDBus(ctx, opts) {
+		return dbusIsActive(ctx, unit, opts)
+	}
+	return execIsActive(ctx, unit, opts)
+}
+
+func execIsActive(ctx context.Context, unit string, opts Options) (bool, error) {
+	stdout, stderr, code, err := run(ctx, opts, "is-active", unit)
+	if err != nil {
+		return false, err
+	}
+	if code != 0 && code != 3 {
+		return false, mapError(code, stderr)
+	}
+	return strings.TrimSpace(stdout) == "active", nil
+}
+
+// IsEnabled reports whether the unit is enabled.
+func IsEnabled(ctx context.Context, unit string, opts Options) (bool, error) {
+	if useDBus(ctx, opts) {
+		return dbusIsEnabled(ctx, unit, opts)
+	}
+	return execIsEnabled(ctx, unit, opts)
+}
+
+func execIsEnabled(ctx context.Context, unit string, opts Options) (bool, error) {
+	stdout, stderr, code, err := run(ctx, opts, "is-enabled", unit)
+	if err != nil {
+		return false, err
+	}
+	if code != 0 && code != 1 {
+		return false, mapError(code, stderr)
+	}
+	return strings.TrimSpace(stdout) == "enabled", nil
+}
+
+// IsFailed reports whether the unit is in a failed state.
+func IsFailed(ctx context.Context, unit string, opts Options) (bool, error) {
+	if useDBus(ctx, opts) {
+		return dbusIsFailed(ctx, unit, opts)
+	}
+	return execIsFailed(ctx, unit, opts)
+}
+
+func execIsFailed(ctx context.Context, unit string, opts Options) (bool, error) {
+	stdout, stderr, code, err := run(ctx, opts, "is-failed", unit)
+	if err != nil {
+		return false, err
+	}
+	if code != 0 && code != 1 && code != 3 {
+		return false, mapError(code, stderr)
+	}
+	return strings.TrimSpace(stdout) == "failed", nil
+}
+
+// DaemonReload runs `systemctl daemon-reload`.
+func DaemonReload(ctx context.Context, opts Options) error {
+	if useDBus(ctx, opts) {
+		return dbusDaemonReload(ctx, opts)
+	}
+	_, stderr, code, err := run(ctx, opts, "daemon-reload")
+	if err != nil {
+		return err
+	}
+	return mapError(code, stderr)
+}
+
+// Show runs `systemctl show -p <property> <unit>` and returns the raw value
+// string, e.g. "ActiveState=active" becomes "active".
+func Show(ctx context.Context, unit string, property properties.Property, opts Options) (string, error) {
+	if useDBus(ctx, opts) {
+		return dbusShow(ctx, unit, property, opts)
+	}
+	return execShow(ctx, unit, property, opts)
+}
+
+func execShow(ctx context.Context, unit string, property properties.Property, opts Options) (string, error) {
+	stdout, stderr, code, err := run(ctx, opts, "show", unit, "-p", string(property))
+	if err != nil {
+		return "", err
+	}
+	if code != 0 {
+		return "", mapError(code, stderr)
+	}
+	line := strings.TrimSpace(stdout)
+	_, value, found := strings.Cut(line, "=")
+	if !found {
+		return "", errors.New("systemctl: unexpected show output: " + line)
+	}
+	return value, nil
+}
@@ -0,0 +1,146 @@
+// Package systemctltest provides a fake systemctl.Execer for exercising the
+// systemctl package without a real systemd, root, or any installed units.
+package systemctltest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/taigrr/systemctl"
+)
+
+var (
+	_ systemctl.Execer = (*Fake)(nil)
+	_ systemctl.Execer = (*Recorder)(nil)
+)
+
+// Result is the canned response a Fake returns for a matching command.
+type Result struct {
+	Stdout string
+	Stderr string
+	Code   int
+	Err    error
+}
+
+// Fake is a table-driven systemctl.Execer. Rules are matched against the
+// argv systemctl would have been invoked with, from the last rule in Rules
+// to the first; the most recently added rule whose Args is a prefix of the
+// actual args wins. Register rules with When, or populate Rules directly.
+type Fake struct {
+	mu    sync.Mutex
+	Rules []Rule
+
+	// Calls records every invocation seen by Run, in order, for assertions
+	// in tests that care what was actually executed.
+	Calls [][]string
+}
+
+// Rule pairs an argv prefix with the Result to return when it matches.
+type Rule struct {
+	Args   []string
+	Result Result
+}
+
+// When registers a rule matching any invocation whose args start with
+// prefix, returning result. Rules are matched most-recently-registered
+// first, so a test can call When again with the same prefix partway through
+// to change what happens next (e.g. masking a unit then re-registering its
+// enable rule to return ErrMasked).
+func (f *Fake) When(prefix []string, result Result) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Rules = append(f.Rules, Rule{Args: prefix, Result: result})
+}
+
+// Run implements systemctl.Execer.
+func (f *Fake) Run(_ context.Context, name string, args ...string) (string, string, int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Calls = append(f.Calls, append([]string{name}, args...))
+	for i := len(f.Rules) - 1; i >= 0; i-- {
+		rule := f.Rules[i]
+		if hasPrefix(args, rule.Args) {
+			return rule.Result.Stdout, rule.Result.Stderr, rule.Result.Code, rule.Result.Err
+		}
+	}
+	return "", "", 0, fmt.Errorf("systemctltest: no rule matches %q", append([]string{name}, args...))
+}
+
+func hasPrefix(args, prefix []string) bool {
+	if len(prefix) > len(args) {
+		return false
+	}
+	for i, p := range prefix {
+		if args[i] != p {
+			return false
+		}
+	}
+	return true
+}
+
+// recordedCall is the on-disk shape used by Record/Load to persist
+// real systemctl output for replay in CI.
+type recordedCall struct {
+	Args   []string `json:"args"`
+	Stdout string   `json:"stdout"`
+	Stderr string   `json:"stderr"`
+	Code   int      `json:"code"`
+}
+
+// Recorder wraps a real systemctl.Execer and appends every call that ran
+// (Real.Run returned a nil error) to an in-memory log, to be written out
+// with Save for later replay via Load.
+// Run it once on a developer machine with the real backend, commit the
+// resulting fixture, and point CI at it with Load instead.
+type Recorder struct {
+	Real systemctl.Execer
+
+	mu      sync.Mutex
+	entries []recordedCall
+}
+
+// Run implements systemctl.Execer, delegating to Real and recording the
+// result.
+func (r *Recorder) Run(ctx context.Context, name string, args ...string) (string, string, int, error) {
+	stdout, stderr, code, err := r.Real.Run(ctx, name, args...)
+	if err == nil {
+		r.mu.Lock()
+		r.entries = append(r.entries, recordedCall{Args: args, Stdout: stdout, Stderr: stderr, Code: code})
+		r.mu.Unlock()
+	}
+	return stdout, stderr, code, err
+}
+
+// Save writes the recorded calls to path as JSON.
+func (r *Recorder) Save(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, err := json.MarshalIndent(r.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Load builds a Fake whose rules replay the fixture written by Recorder.Save.
+// Each recorded call's args become a rule registered with When, so they
+// match as a prefix like any other rule, and a later recording of the same
+// args takes precedence over an earlier one.
+func Load(path string) (*Fake, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []recordedCall
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	fake := &Fake{}
+	for _, e := range entries {
+		fake.When(e.Args, Result{Stdout: e.Stdout, Stderr: e.Stderr, Code: e.Code})
+	}
+	return fake, nil
+}
@@ -0,0 +1,288 @@
+package systemctl
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/taigrr/systemctl/properties"
+)
+
+// defaultWatchProperties is used when Options.WatchProperties is empty.
+var defaultWatchProperties = []properties.Property{
+	properties.ActiveState,
+	properties.SubState,
+	properties.LoadState,
+	properties.MainPID,
+	properties.ExecMainCode,
+	properties.ExecMainStatus,
+}
+
+// Event is one property transition reported by Watch.
+type Event struct {
+	Time     time.Time
+	Property properties.Property
+	Old      string
+	New      string
+}
+
+// UnitState is a snapshot of the properties WaitUntil's predicate is
+// evaluated against; it only has fields for the properties Watch tracks by
+// default; properties added via Options.WatchProperties beyond that set are
+// reported through Watch's Event channel but not reflected here.
+type UnitState struct {
+	ActiveState    string
+	SubState       string
+	LoadState      string
+	MainPID        string
+	ExecMainCode   string
+	ExecMainStatus string
+}
+
+func (s UnitState) apply(ev Event) UnitState {
+	switch ev.Property {
+	case properties.ActiveState:
+		s.ActiveState = ev.New
+	case properties.SubState:
+		s.SubState = ev.New
+	case properties.LoadState:
+		s.LoadState = ev.New
+	case properties.MainPID:
+		s.MainPID = ev.New
+	case properties.ExecMainCode:
+		s.ExecMainCode = ev.New
+	case properties.ExecMainStatus:
+		s.ExecMainStatus = ev.New
+	}
+	return s
+}
+
+func currentState(ctx context.Context, unit string, opts Options) (UnitState, error) {
+	var (
+		state UnitState
+		err   error
+	)
+	if state.ActiveState, err = Show(ctx, unit, properties.ActiveState, opts); err != nil {
+		return state, err
+	}
+	if state.SubState, err = Show(ctx, unit, properties.SubState, opts); err != nil {
+		return state, err
+	}
+	if state.LoadState, err = Show(ctx, unit, properties.LoadState, opts); err != nil {
+		return state, err
+	}
+	if state.MainPID, err = Show(ctx, unit, properties.MainPID, opts); err != nil {
+		return state, err
+	}
+	if state.ExecMainCode, err = Show(ctx, unit, properties.ExecMainCode, opts); err != nil {
+		return state, err
+	}
+	if state.ExecMainStatus, err = Show(ctx, unit, properties.ExecMainStatus, opts); err != nil {
+		return state, err
+	}
+	return state, nil
+}
+
+// watchProperties resolves which properties to track for opts, defaulting
+// to the set UnitState understands.
+func watchProperties(opts Options) []properties.Property {
+	if len(opts.WatchProperties) > 0 {
+		return opts.WatchProperties
+	}
+	return defaultWatchProperties
+}
+
+// Watch streams property transitions for unit until ctx is canceled, at
+// which point the returned channel is closed. It prefers subscribing to the
+// D-Bus backend's PropertiesChanged signal and falls back to polling Show
+// for the properties in Options.WatchProperties (or a sensible default)
+// when D-Bus isn't available. Options.WatchCoalesce, when non-zero, drops a
+// transition of a property if one was already emitted for it within that
+// window, so a rapidly oscillating unit doesn't drown the channel; a dropped
+// transition, including the last one in the window, is not sent later.
+func Watch(ctx context.Context, unit string, opts Options) (<-chan Event, error) {
+	if _, err := Show(ctx, unit, properties.ActiveState, opts); err != nil {
+		return nil, err
+	}
+
+	props := watchProperties(opts)
+	events := make(chan Event, 16)
+
+	if useDBus(ctx, opts) {
+		if err := dbusWatch(ctx, unit, props, opts, events); err == nil {
+			return events, nil
+		}
+	}
+	go pollWatch(ctx, unit, props, opts, events)
+	return events, nil
+}
+
+func pollWatch(ctx context.Context, unit string, props []properties.Property, opts Options, events chan<- Event) {
+	defer close(events)
+
+	interval := opts.WatchInterval
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := make(map[properties.Property]string, len(props))
+	lastEmit := make(map[properties.Property]time.Time, len(props))
+	for _, p := range props {
+		if value, err := Show(ctx, unit, p, opts); err == nil {
+			last[p] = value
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now()
+			for _, p := range props {
+				value, err := Show(ctx, unit, p, opts)
+				if err != nil || value == last[p] {
+					continue
+				}
+				if opts.WatchCoalesce > 0 && now.Sub(lastEmit[p]) < opts.WatchCoalesce {
+					last[p] = value
+					continue
+				}
+				old := last[p]
+				last[p] = value
+				lastEmit[p] = now
+				select {
+				case events <- Event{Time: now, Property: p, Old: old, New: value}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+// dbusWatch subscribes to PropertiesChanged on unit's object path. It
+// returns an error immediately if the bus or the unit's object path can't
+// be resolved; once subscribed, delivery runs in its own goroutine until ctx
+// is canceled.
+func dbusWatch(ctx context.Context, unit string, props []properties.Property, opts Options, events chan<- Event) error {
+	conn, err := connectBus(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	unitPath, err := loadUnitPath(ctx, conn, unit)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	managerObj := conn.Object(systemdDest, systemdObjectPath)
+	if err := managerObj.CallWithContext(ctx, managerIface+".Subscribe", 0).Err; err != nil {
+		conn.Close()
+		return translateDBusError(err)
+	}
+
+	tracked := make(map[properties.Property]bool, len(props))
+	for _, p := range props {
+		tracked[p] = true
+	}
+
+	matchRule := "type='signal',interface='org.freedesktop.DBus.Properties',member='PropertiesChanged',path='" + string(unitPath) + "'"
+	if err := conn.AddMatchSignal(dbus.WithMatchOption("type", "signal")); err != nil {
+		// best-effort: fall back to the raw rule string for older godbus
+		_ = conn.BusObject().CallWithContext(ctx, "org.freedesktop.DBus.AddMatch", 0, matchRule)
+	}
+
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+
+	last := make(map[properties.Property]string, len(props))
+	lastEmit := make(map[properties.Property]time.Time, len(props))
+	for _, p := range props {
+		if value, err := Show(ctx, unit, p, opts); err == nil {
+			last[p] = value
+		}
+	}
+
+	go func() {
+		defer close(events)
+		defer conn.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig, ok := <-signals:
+				if !ok {
+					return
+				}
+				if sig.Path != unitPath || len(sig.Body) < 2 {
+					continue
+				}
+				changed, ok := sig.Body[1].(map[string]dbus.Variant)
+				if !ok {
+					continue
+				}
+				now := time.Now()
+				for name, variant := range changed {
+					p := properties.Property(name)
+					if !tracked[p] {
+						continue
+					}
+					value := strings.Trim(variant.String(), `"`)
+					if value == last[p] {
+						continue
+					}
+					if opts.WatchCoalesce > 0 && now.Sub(lastEmit[p]) < opts.WatchCoalesce {
+						last[p] = value
+						continue
+					}
+					old := last[p]
+					last[p] = value
+					lastEmit[p] = now
+					select {
+					case events <- Event{Time: now, Property: p, Old: old, New: value}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// WaitUntil blocks until predicate(state) is true for unit's state, or ctx
+// is canceled. It checks the current state first so callers don't race a
+// transition that already happened before Watch subscribed.
+func WaitUntil(ctx context.Context, unit string, predicate func(UnitState) bool, opts Options) error {
+	state, err := currentState(ctx, unit, opts)
+	if err != nil {
+		return err
+	}
+	if predicate(state) {
+		return nil
+	}
+
+	events, err := Watch(ctx, unit, opts)
+	if err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev, ok := <-events:
+			if !ok {
+				return ctx.Err()
+			}
+			state = state.apply(ev)
+			if predicate(state) {
+				return nil
+			}
+		}
+	}
+}
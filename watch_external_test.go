@@ -0,0 +1,149 @@
+package systemctl_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	systemctl "github.com/taigrr/systemctl"
+	"github.com/taigrr/systemctl/properties"
+	"github.com/taigrr/systemctl/systemctltest"
+)
+
+// baselineWatchFake registers a Fake returning a steady set of values for
+// every property Watch tracks by default, with ActiveState pinned to
+// initialActiveState. Tests reconfigure ActiveState afterwards with
+// fake.When to simulate the unit transitioning.
+func baselineWatchFake(initialActiveState string) *systemctltest.Fake {
+	fake := &systemctltest.Fake{}
+	fake.When([]string{"show", "nginx", "-p", "ActiveState"}, systemctltest.Result{Code: 0, Stdout: "ActiveState=" + initialActiveState + "\n"})
+	fake.When([]string{"show", "nginx", "-p", "SubState"}, systemctltest.Result{Code: 0, Stdout: "SubState=running\n"})
+	fake.When([]string{"show", "nginx", "-p", "LoadState"}, systemctltest.Result{Code: 0, Stdout: "LoadState=loaded\n"})
+	fake.When([]string{"show", "nginx", "-p", "MainPID"}, systemctltest.Result{Code: 0, Stdout: "MainPID=123\n"})
+	fake.When([]string{"show", "nginx", "-p", "ExecMainCode"}, systemctltest.Result{Code: 0, Stdout: "ExecMainCode=0\n"})
+	fake.When([]string{"show", "nginx", "-p", "ExecMainStatus"}, systemctltest.Result{Code: 0, Stdout: "ExecMainStatus=0\n"})
+	return fake
+}
+
+func setActiveState(fake *systemctltest.Fake, value string) {
+	fake.When([]string{"show", "nginx", "-p", "ActiveState"}, systemctltest.Result{Code: 0, Stdout: "ActiveState=" + value + "\n"})
+}
+
+// This test needs systemctltest.Fake, which imports systemctl itself, so it
+// lives in the external test package alongside systemctl_test.go and
+// batch_test.go. See watch_test.go for the internal-only Watch tests.
+
+func TestWaitUntilReturnsImmediatelyWhenAlreadySatisfied(t *testing.T) {
+	fake := baselineWatchFake("active")
+
+	opts := systemctl.Options{Executer: fake, Backend: systemctl.BackendExec}
+	err := systemctl.WaitUntil(context.Background(), "nginx", func(s systemctl.UnitState) bool {
+		return s.ActiveState == "active"
+	}, opts)
+	if err != nil {
+		t.Errorf("WaitUntil() = %v, want nil", err)
+	}
+}
+
+// TestWatchEmitsEventOnChange drives Watch's polling fallback (BackendExec,
+// via the fake) and asserts it reports the transition with the right
+// Old/New values once the fake's Show output changes underneath it.
+func TestWatchEmitsEventOnChange(t *testing.T) {
+	fake := baselineWatchFake("activating")
+	opts := systemctl.Options{Executer: fake, Backend: systemctl.BackendExec, WatchInterval: 10 * time.Millisecond}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	events, err := systemctl.Watch(ctx, "nginx", opts)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	setActiveState(fake, "active")
+
+	select {
+	case ev := <-events:
+		if ev.Property != properties.ActiveState || ev.Old != "activating" || ev.New != "active" {
+			t.Errorf("event = %+v, want Property=ActiveState Old=activating New=active", ev)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("timed out waiting for a Watch event")
+	}
+}
+
+// TestWatchCoalescesRapidTransitions checks that oscillating a property
+// several times within Options.WatchCoalesce's window only produces the
+// first transition, instead of one event per poll.
+func TestWatchCoalescesRapidTransitions(t *testing.T) {
+	fake := baselineWatchFake("inactive")
+	opts := systemctl.Options{
+		Executer:      fake,
+		Backend:       systemctl.BackendExec,
+		WatchInterval: 5 * time.Millisecond,
+		WatchCoalesce: 200 * time.Millisecond,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	events, err := systemctl.Watch(ctx, "nginx", opts)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	for _, state := range []string{"activating", "active", "deactivating", "inactive"} {
+		time.Sleep(15 * time.Millisecond)
+		setActiveState(fake, state)
+	}
+
+	var got []systemctl.Event
+	timeout := time.After(120 * time.Millisecond)
+collect:
+	for {
+		select {
+		case ev := <-events:
+			got = append(got, ev)
+		case <-timeout:
+			break collect
+		}
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("got %d events during the coalesce window, want exactly 1: %+v", len(got), got)
+	}
+	if got[0].Old != "inactive" || got[0].New != "activating" {
+		t.Errorf("event = %+v, want Old=inactive New=activating", got[0])
+	}
+}
+
+// TestWaitUntilBlocksUntilWatchedEventSatisfiesPredicate exercises the path
+// where WaitUntil's current state doesn't satisfy the predicate and it has
+// to wait on an Event from Watch.
+func TestWaitUntilBlocksUntilWatchedEventSatisfiesPredicate(t *testing.T) {
+	fake := baselineWatchFake("activating")
+	opts := systemctl.Options{Executer: fake, Backend: systemctl.BackendExec, WatchInterval: 10 * time.Millisecond}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- systemctl.WaitUntil(ctx, "nginx", func(s systemctl.UnitState) bool {
+			return s.ActiveState == "active"
+		}, opts)
+	}()
+
+	time.Sleep(30 * time.Millisecond)
+	setActiveState(fake, "active")
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("WaitUntil() = %v, want nil", err)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("timed out waiting for WaitUntil to unblock")
+	}
+}
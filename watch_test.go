@@ -0,0 +1,26 @@
+package systemctl
+
+import (
+	"testing"
+
+	"github.com/taigrr/systemctl/properties"
+)
+
+func TestWatchPropertiesDefaultsAndOverride(t *testing.T) {
+	if got := watchProperties(Options{}); len(got) != len(defaultWatchProperties) {
+		t.Errorf("watchProperties(Options{}) = %v, want the default set", got)
+	}
+	custom := []properties.Property{properties.ActiveState}
+	if got := watchProperties(Options{WatchProperties: custom}); len(got) != 1 || got[0] != properties.ActiveState {
+		t.Errorf("watchProperties() with override = %v, want %v", got, custom)
+	}
+}
+
+func TestUnitStateApply(t *testing.T) {
+	var state UnitState
+	state = state.apply(Event{Property: properties.ActiveState, New: "active"})
+	state = state.apply(Event{Property: properties.SubState, New: "running"})
+	if state.ActiveState != "active" || state.SubState != "running" {
+		t.Errorf("state = %+v, want ActiveState=active SubState=running", state)
+	}
+}